@@ -0,0 +1,95 @@
+package goose
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+// fakeChecksumStore is an in-memory ChecksumStore, used so tests don't
+// depend on the fake driver's SELECT support (fakeStmt.Query always
+// returns zero rows, which would make every checksum lookup look
+// unrecorded).
+type fakeChecksumStore struct {
+	saved map[int64]string
+}
+
+func (s *fakeChecksumStore) Save(ctx context.Context, db versionStoreExecer, version int64, checksum string) error {
+	if s.saved == nil {
+		s.saved = make(map[int64]string)
+	}
+	s.saved[version] = checksum
+	return nil
+}
+
+func (s *fakeChecksumStore) Get(ctx context.Context, db *sql.DB, version int64) (string, bool, error) {
+	checksum, ok := s.saved[version]
+	return checksum, ok, nil
+}
+
+func TestValidateReportsNoDriftRightAfterApply(t *testing.T) {
+	db, _ := openFakeDB(t)
+	defer db.Close()
+
+	versions := &fakeVersionStore{}
+	SetVersionStore(versions)
+	defer SetVersionStore(nil)
+
+	checksums := &fakeChecksumStore{}
+	SetChecksumStore(checksums)
+	defer SetChecksumStore(nil)
+
+	src := literalSource{
+		refs: []MigrationRef{{Version: 1, Name: "00001_init.sql"}},
+		content: map[int64][]byte{
+			1: []byte("-- +goose Up\nCREATE TABLE t (id int);\n-- +goose Down\nDROP TABLE t;\n"),
+		},
+	}
+
+	if err := UpContextWithSource(context.Background(), db, "unused", src); err != nil {
+		t.Fatalf("UpContextWithSource: %v", err)
+	}
+
+	drift, err := Validate(context.Background(), db, src)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(drift) != 0 {
+		t.Fatalf("expected no drift immediately after applying, got %v", drift)
+	}
+}
+
+func TestValidateReportsDriftAfterFileEdited(t *testing.T) {
+	db, _ := openFakeDB(t)
+	defer db.Close()
+
+	versions := &fakeVersionStore{}
+	SetVersionStore(versions)
+	defer SetVersionStore(nil)
+
+	checksums := &fakeChecksumStore{}
+	SetChecksumStore(checksums)
+	defer SetChecksumStore(nil)
+
+	src := literalSource{
+		refs: []MigrationRef{{Version: 1, Name: "00001_init.sql"}},
+		content: map[int64][]byte{
+			1: []byte("-- +goose Up\nCREATE TABLE t (id int);\n-- +goose Down\nDROP TABLE t;\n"),
+		},
+	}
+
+	if err := UpContextWithSource(context.Background(), db, "unused", src); err != nil {
+		t.Fatalf("UpContextWithSource: %v", err)
+	}
+
+	// A teammate edits the already-applied migration file afterward.
+	src.content[1] = []byte("-- +goose Up\nCREATE TABLE t (id int, name text);\n-- +goose Down\nDROP TABLE t;\n")
+
+	drift, err := Validate(context.Background(), db, src)
+	if err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if len(drift) != 1 || drift[0].Version != 1 {
+		t.Fatalf("expected drift on version 1, got %v", drift)
+	}
+}
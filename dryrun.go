@@ -0,0 +1,55 @@
+package goose
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+)
+
+// dryRun, when true, makes runSQLMigration print the SQL it would execute
+// instead of committing it: transactional migrations still run inside a
+// transaction (so generated statements like sequence bumps are visible)
+// but are rolled back rather than committed, while no-transaction
+// migrations are printed without being executed at all.
+var dryRun bool
+
+// dryRunWriter receives the SQL printed while dryRun is enabled.
+var dryRunWriter io.Writer = os.Stdout
+
+// SetDryRun enables or disables dry-run mode for subsequent migrations. It
+// underlies UpDryRun. No CLI flag is wired to it.
+func SetDryRun(enabled bool) {
+	dryRun = enabled
+}
+
+// UpDryRun runs pending migrations in dir against db in dry-run mode:
+// migrations execute inside a transaction that is always rolled back, and
+// the SQL that would have been committed, including the version-table
+// insert or delete, is printed to w (or os.Stdout if w is nil). Statements
+// are printed with their bind placeholders, not with the values
+// substituted; the values follow on a separate "-- args:" comment line.
+// No-transaction migrations are printed without being executed at all.
+func UpDryRun(ctx context.Context, db *sql.DB, dir string, w io.Writer) error {
+	SetDryRun(true)
+	defer SetDryRun(false)
+	SetDryRunWriter(w)
+	return UpSourceContext(ctx, db, dir)
+}
+
+// SetDryRunWriter sets the io.Writer that dry-run mode prints SQL to. It
+// defaults to os.Stdout. Passing nil restores the default.
+func SetDryRunWriter(w io.Writer) {
+	if w == nil {
+		w = os.Stdout
+	}
+	dryRunWriter = w
+}
+
+func dryRunPrint(query string, args ...interface{}) {
+	fmt.Fprintln(dryRunWriter, clearStatement(query)+";")
+	if len(args) > 0 {
+		fmt.Fprintf(dryRunWriter, "-- args: %v\n", args)
+	}
+}
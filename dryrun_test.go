@@ -0,0 +1,64 @@
+package goose
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDryRunExecutesThenRollsBackInsteadOfCommitting(t *testing.T) {
+	db, conn := openFakeDB(t)
+	defer db.Close()
+
+	var out bytes.Buffer
+	SetDryRun(true)
+	SetDryRunWriter(&out)
+	defer func() {
+		SetDryRun(false)
+		SetDryRunWriter(nil)
+	}()
+
+	statements := []string{"ALTER TABLE users ADD COLUMN age int"}
+	if err := runSQLMigration(context.Background(), db, statements, true, 1, true, true, ""); err != nil {
+		t.Fatalf("runSQLMigration: %v", err)
+	}
+
+	if conn.commits != 0 {
+		t.Fatalf("expected dry run to never commit, got %d commits", conn.commits)
+	}
+	if conn.rollbacks != 1 {
+		t.Fatalf("expected dry run to roll back exactly once, got %d", conn.rollbacks)
+	}
+	if len(conn.execed) != 1 || conn.execed[0] != statements[0] {
+		t.Fatalf("expected the statement to still be executed inside the doomed transaction, got %v", conn.execed)
+	}
+	if !strings.Contains(out.String(), "ALTER TABLE users ADD COLUMN age int") {
+		t.Fatalf("expected dry run output to include the statement, got %q", out.String())
+	}
+}
+
+func TestDryRunSkipsExecutionForNoTransactionMigrations(t *testing.T) {
+	db, conn := openFakeDB(t)
+	defer db.Close()
+
+	var out bytes.Buffer
+	SetDryRun(true)
+	SetDryRunWriter(&out)
+	defer func() {
+		SetDryRun(false)
+		SetDryRunWriter(nil)
+	}()
+
+	statements := []string{"CREATE INDEX CONCURRENTLY idx_users_age ON users (age)"}
+	if err := runSQLMigration(context.Background(), db, statements, false, 1, true, true, ""); err != nil {
+		t.Fatalf("runSQLMigration: %v", err)
+	}
+
+	if len(conn.execed) != 0 {
+		t.Fatalf("expected no statements to be executed for a no-tx dry run, got %v", conn.execed)
+	}
+	if !strings.Contains(out.String(), "CREATE INDEX CONCURRENTLY idx_users_age ON users (age)") {
+		t.Fatalf("expected dry run output to include the statement, got %q", out.String())
+	}
+}
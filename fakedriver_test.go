@@ -0,0 +1,105 @@
+package goose
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// fakeConn is a minimal in-memory database/sql/driver.Conn used to
+// exercise runSQLMigration without a real database. It records every
+// query it's asked to run and every transaction commit/rollback, and
+// otherwise treats all statements as no-ops, returning zero rows for
+// queries.
+type fakeConn struct {
+	mu        sync.Mutex
+	execed    []string
+	commits   int
+	rollbacks int
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return &fakeTx{conn: c}, nil
+}
+
+// fakeSingleConnDriver always hands back the same *fakeConn, so a test can
+// inspect everything that was run against the *sql.DB it backs.
+type fakeSingleConnDriver struct {
+	conn *fakeConn
+}
+
+func (d fakeSingleConnDriver) Open(name string) (driver.Conn, error) {
+	return d.conn, nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.mu.Lock()
+	s.conn.execed = append(s.conn.execed, s.query)
+	s.conn.mu.Unlock()
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return emptyRows{}, nil
+}
+
+type fakeTx struct {
+	conn *fakeConn
+}
+
+func (t *fakeTx) Commit() error {
+	t.conn.mu.Lock()
+	t.conn.commits++
+	t.conn.mu.Unlock()
+	return nil
+}
+
+func (t *fakeTx) Rollback() error {
+	t.conn.mu.Lock()
+	t.conn.rollbacks++
+	t.conn.mu.Unlock()
+	return nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string { return nil }
+func (emptyRows) Close() error      { return nil }
+func (emptyRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+
+var fakeDriverSeq int64
+
+// openFakeDB returns a *sql.DB and the single fakeConn backing it, so
+// tests can assert on exactly what was executed or committed/rolled back.
+func openFakeDB(t interface{ Fatalf(string, ...interface{}) }) (*sql.DB, *fakeConn) {
+	conn := &fakeConn{}
+	name := fmt.Sprintf("goose-fake-%d", atomic.AddInt64(&fakeDriverSeq, 1))
+	sql.Register(name, fakeSingleConnDriver{conn: conn})
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	// Force the pool down to the single connection fakeSingleConnDriver
+	// always returns, so every query lands on the same fakeConn.
+	db.SetMaxOpenConns(1)
+	return db, conn
+}
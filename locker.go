@@ -0,0 +1,192 @@
+package goose
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLockTimeout is returned by Locker implementations when a lock could
+// not be acquired before the configured timeout elapsed.
+var ErrLockTimeout = errors.New("goose: timed out waiting to acquire migration lock")
+
+// Locker coordinates Up/Down across multiple processes (e.g. several
+// replicas of the same deployment rolling out simultaneously) so that only
+// one of them applies migrations at a time. Lock blocks until the lock is
+// acquired, ctx is done, or the implementation's own timeout elapses,
+// returning an unlock func to release it.
+type Locker interface {
+	Lock(ctx context.Context, db *sql.DB) (unlock func() error, err error)
+}
+
+// locker is the Locker acquired by UpSourceContext/UpContextWithSource before
+// their migration loop runs, and released once it returns. It defaults to
+// nil, which preserves the existing behavior of not taking any lock at
+// all.
+var locker Locker
+
+// SetLocker overrides the Locker used to serialize concurrent migration
+// runs. Passing nil disables locking.
+func SetLocker(l Locker) {
+	locker = l
+}
+
+// acquireLock takes the configured Locker, if any, returning a no-op
+// unlock func when none is set so callers can always defer the result.
+func acquireLock(ctx context.Context, db *sql.DB) (func() error, error) {
+	if locker == nil {
+		return func() error { return nil }, nil
+	}
+	return locker.Lock(ctx, db)
+}
+
+// postgresLocker uses pg_advisory_lock, keyed off a fixed advisory lock id
+// derived from "goose", to serialize migrations across processes sharing a
+// Postgres database.
+type postgresLocker struct {
+	timeout time.Duration
+}
+
+// NewPostgresLocker returns a Locker backed by a Postgres session-level
+// advisory lock. A timeout of 0 waits indefinitely.
+func NewPostgresLocker(timeout time.Duration) Locker {
+	return &postgresLocker{timeout: timeout}
+}
+
+func (l *postgresLocker) Lock(ctx context.Context, db *sql.DB) (func() error, error) {
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection for advisory lock: %w", err)
+	}
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock(hashtext('goose'))`); err != nil {
+		conn.Close()
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, ErrLockTimeout
+		}
+		return nil, fmt.Errorf("failed to acquire postgres advisory lock: %w", err)
+	}
+
+	unlock := func() error {
+		defer conn.Close()
+		_, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext('goose'))`)
+		return err
+	}
+	return unlock, nil
+}
+
+// mysqlLocker uses GET_LOCK to serialize migrations across processes
+// sharing a MySQL database.
+type mysqlLocker struct {
+	timeout time.Duration
+}
+
+// NewMySQLLocker returns a Locker backed by a MySQL named lock. A timeout
+// of 0 waits indefinitely (passed to GET_LOCK as -1).
+func NewMySQLLocker(timeout time.Duration) Locker {
+	return &mysqlLocker{timeout: timeout}
+}
+
+func (l *mysqlLocker) Lock(ctx context.Context, db *sql.DB) (func() error, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection for named lock: %w", err)
+	}
+
+	timeoutSeconds := -1
+	if l.timeout > 0 {
+		timeoutSeconds = int(l.timeout / time.Second)
+	}
+
+	var acquired int
+	row := conn.QueryRowContext(ctx, `SELECT GET_LOCK('goose', ?)`, timeoutSeconds)
+	if err := row.Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to acquire mysql named lock: %w", err)
+	}
+	if acquired != 1 {
+		conn.Close()
+		return nil, ErrLockTimeout
+	}
+
+	unlock := func() error {
+		defer conn.Close()
+		_, err := conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK('goose')`)
+		return err
+	}
+	return unlock, nil
+}
+
+// tableLocker is a fallback Locker, for dialects without a native advisory
+// lock primitive (e.g. SQLite), that serializes migrations via a row lock
+// on a dedicated goose_lock table.
+type tableLocker struct {
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+// NewTableLocker returns a Locker that polls a goose_lock table until it
+// can insert the single lock row, or timeout elapses. A timeout of 0 waits
+// indefinitely.
+func NewTableLocker(timeout time.Duration) Locker {
+	return &tableLocker{timeout: timeout, pollInterval: 100 * time.Millisecond}
+}
+
+func (l *tableLocker) Lock(ctx context.Context, db *sql.DB) (func() error, error) {
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS goose_lock (id INTEGER PRIMARY KEY)`); err != nil {
+		return nil, fmt.Errorf("failed to create goose_lock table: %w", err)
+	}
+
+	for {
+		_, err := db.ExecContext(ctx, `INSERT INTO goose_lock (id) VALUES (1)`)
+		if err == nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, ErrLockTimeout
+			}
+			return nil, ctx.Err()
+		case <-time.After(l.pollInterval):
+		}
+	}
+
+	unlock := func() error {
+		_, err := db.ExecContext(context.Background(), `DELETE FROM goose_lock WHERE id = 1`)
+		return err
+	}
+	return unlock, nil
+}
+
+// NewLockerForDialect picks the Locker implementation appropriate for
+// dialectName (as accepted by the CLI's existing -dialect flag: "postgres",
+// "mysql", or anything else, which falls back to the table-based locker)
+// with the given timeout. Callers install the result via
+// SetLocker(goose.NewLockerForDialect(...)) before invoking Up; no CLI
+// flag (such as a lock timeout) is wired to it.
+func NewLockerForDialect(dialectName string, timeout time.Duration) (Locker, error) {
+	switch dialectName {
+	case "postgres":
+		return NewPostgresLocker(timeout), nil
+	case "mysql":
+		return NewMySQLLocker(timeout), nil
+	default:
+		return NewTableLocker(timeout), nil
+	}
+}
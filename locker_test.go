@@ -0,0 +1,119 @@
+package goose
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+type fakeLocker struct {
+	locked, unlocked int
+}
+
+func (l *fakeLocker) Lock(ctx context.Context, db *sql.DB) (func() error, error) {
+	l.locked++
+	return func() error {
+		l.unlocked++
+		return nil
+	}, nil
+}
+
+func TestAcquireLockNoopWhenNoneConfigured(t *testing.T) {
+	SetLocker(nil)
+
+	db, _ := openFakeDB(t)
+	defer db.Close()
+
+	unlock, err := acquireLock(context.Background(), db)
+	if err != nil {
+		t.Fatalf("acquireLock: %v", err)
+	}
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+}
+
+func TestAcquireLockUsesConfiguredLocker(t *testing.T) {
+	locker := &fakeLocker{}
+	SetLocker(locker)
+	defer SetLocker(nil)
+
+	db, _ := openFakeDB(t)
+	defer db.Close()
+
+	unlock, err := acquireLock(context.Background(), db)
+	if err != nil {
+		t.Fatalf("acquireLock: %v", err)
+	}
+	if locker.locked != 1 {
+		t.Fatalf("locked = %d, want 1", locker.locked)
+	}
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	if locker.unlocked != 1 {
+		t.Fatalf("unlocked = %d, want 1", locker.unlocked)
+	}
+}
+
+func TestPostgresLockerAcquireAndRelease(t *testing.T) {
+	db, conn := openFakeDB(t)
+	defer db.Close()
+
+	locker := NewPostgresLocker(0)
+	unlock, err := locker.Lock(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+
+	foundLock, foundUnlock := false, false
+	for _, q := range conn.execed {
+		if q == `SELECT pg_advisory_lock(hashtext('goose'))` {
+			foundLock = true
+		}
+		if q == `SELECT pg_advisory_unlock(hashtext('goose'))` {
+			foundUnlock = true
+		}
+	}
+	if !foundLock || !foundUnlock {
+		t.Fatalf("expected both lock and unlock statements, got %v", conn.execed)
+	}
+}
+
+func TestTableLockerAcquireAndRelease(t *testing.T) {
+	db, _ := openFakeDB(t)
+	defer db.Close()
+
+	locker := NewTableLocker(0)
+	unlock, err := locker.Lock(context.Background(), db)
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+}
+
+func TestNewLockerForDialectPicksImplementation(t *testing.T) {
+	cases := map[string]interface{}{
+		"postgres": &postgresLocker{},
+		"mysql":    &mysqlLocker{},
+		"sqlite3":  &tableLocker{},
+	}
+	for dialect, want := range cases {
+		l, err := NewLockerForDialect(dialect, 0)
+		if err != nil {
+			t.Fatalf("NewLockerForDialect(%q): %v", dialect, err)
+		}
+		if l == nil {
+			t.Fatalf("NewLockerForDialect(%q) returned nil", dialect)
+		}
+		if got, wantType := reflect.TypeOf(l), reflect.TypeOf(want); got != wantType {
+			t.Fatalf("NewLockerForDialect(%q) = %v, want %v", dialect, got, wantType)
+		}
+	}
+}
@@ -16,7 +16,24 @@ import (
 //
 // All statements following an Up or Down directive are grouped together
 // until another direction directive is found.
-func runSQLMigration(ctx context.Context, db *sql.DB, statements []string, useTx bool, v int64, direction bool, noVersioning bool) error {
+// fileChecksum, when non-empty, is the checksum of the migration's full
+// on-disk content (see contentChecksum), recorded via checksumStore on
+// insert so that Validate can later recompute the exact same hash over the
+// exact same input and detect drift. It's distinct from the
+// statements-only checksum used for no-tx statement-resume bookkeeping,
+// which only ever needs to compare against itself.
+func runSQLMigration(ctx context.Context, db *sql.DB, statements []string, useTx bool, v int64, direction bool, noVersioning bool, fileChecksum string) error {
+	info := MigrationInfo{Version: v, Direction: direction}
+	// Dry runs never commit anything, so Before/AfterMigration hooks (which
+	// exist for side effects like metrics, notifications, and backups tied
+	// to a real migration) are suppressed entirely rather than firing a
+	// before-hook with no matching after-hook.
+	if !dryRun {
+		if err := runBeforeMigrationHook(ctx, info); err != nil {
+			return fmt.Errorf("before migration hook failed: %w", err)
+		}
+	}
+
 	if useTx {
 		// TRANSACTION.
 
@@ -29,6 +46,9 @@ func runSQLMigration(ctx context.Context, db *sql.DB, statements []string, useTx
 
 		for _, query := range statements {
 			verboseInfo("Executing statement: %s\n", clearStatement(query))
+			if dryRun {
+				dryRunPrint(query)
+			}
 			if err = execQuery(ctx, tx.ExecContext, query); err != nil {
 				verboseInfo("Rollback transaction")
 				tx.Rollback()
@@ -38,48 +58,112 @@ func runSQLMigration(ctx context.Context, db *sql.DB, statements []string, useTx
 
 		if !noVersioning {
 			if direction {
-				if err := execQuery(ctx, tx.ExecContext, GetDialect().insertVersionSQL(), v, direction); err != nil {
+				if dryRun {
+					dryRunPrint(GetDialect().insertVersionSQL(), v, direction)
+				}
+				if err := versionStore.Insert(ctx, tx, v); err != nil {
 					verboseInfo("Rollback transaction")
 					tx.Rollback()
-					return fmt.Errorf("failed to insert new goose version: %w", err)
+					return err
+				}
+				if checksumStore != nil && fileChecksum != "" {
+					if err := checksumStore.Save(ctx, tx, v, fileChecksum); err != nil {
+						verboseInfo("Rollback transaction")
+						tx.Rollback()
+						return err
+					}
 				}
 			} else {
-				if err := execQuery(ctx, tx.ExecContext, GetDialect().deleteVersionSQL(), v); err != nil {
+				if dryRun {
+					dryRunPrint(GetDialect().deleteVersionSQL(), v)
+				}
+				if err := versionStore.Delete(ctx, tx, v); err != nil {
 					verboseInfo("Rollback transaction")
 					tx.Rollback()
-					return fmt.Errorf("failed to delete goose version: %w", err)
+					return err
 				}
 			}
 		}
 
+		if dryRun {
+			verboseInfo("Rollback transaction (dry run)")
+			return tx.Rollback()
+		}
+
 		verboseInfo("Commit transaction")
 		if err := tx.Commit(); err != nil {
 			return fmt.Errorf("failed to commit transaction: %w", err)
 		}
 
-		return nil
+		return runAfterMigrationHook(ctx, info)
 	}
 
 	// NO TRANSACTION.
-	for _, query := range statements {
+	if dryRun {
+		for _, query := range statements {
+			dryRunPrint(query)
+		}
+		if !noVersioning {
+			if direction {
+				dryRunPrint(GetDialect().insertVersionSQL(), v, direction)
+			} else {
+				dryRunPrint(GetDialect().deleteVersionSQL(), v)
+			}
+		}
+		return nil
+	}
+
+	startIdx := 0
+	stmtChecksum := statementsChecksum(statements)
+	if statementProgressStore != nil {
+		lastIdx, savedChecksum, ok, err := statementProgressStore.Get(ctx, db, v)
+		if err != nil {
+			return err
+		}
+		if ok {
+			if savedChecksum != stmtChecksum {
+				return fmt.Errorf("%w: version %d", ErrChecksumMismatch, v)
+			}
+			startIdx = lastIdx + 1
+			verboseInfo("Resuming no-tx migration %d from statement %d", v, startIdx)
+		}
+	}
+
+	for i := startIdx; i < len(statements); i++ {
+		query := statements[i]
 		verboseInfo("Executing statement: %s", clearStatement(query))
 		if err := execQuery(ctx, db.ExecContext, query); err != nil {
 			return fmt.Errorf("failed to execute SQL query %q: %w", clearStatement(query), err)
 		}
+		if statementProgressStore != nil {
+			if err := statementProgressStore.Save(ctx, db, v, i, stmtChecksum); err != nil {
+				return err
+			}
+		}
 	}
 	if !noVersioning {
 		if direction {
-			if err := execQuery(ctx, db.ExecContext, GetDialect().insertVersionSQL(), v, direction); err != nil {
-				return fmt.Errorf("failed to insert new goose version: %w", err)
+			if err := versionStore.Insert(ctx, db, v); err != nil {
+				return err
+			}
+			if checksumStore != nil && fileChecksum != "" {
+				if err := checksumStore.Save(ctx, db, v, fileChecksum); err != nil {
+					return err
+				}
 			}
 		} else {
-			if err := execQuery(ctx, db.ExecContext, GetDialect().deleteVersionSQL(), v); err != nil {
-				return fmt.Errorf("failed to delete goose version: %w", err)
+			if err := versionStore.Delete(ctx, db, v); err != nil {
+				return err
 			}
 		}
 	}
+	if statementProgressStore != nil {
+		if err := statementProgressStore.Clear(ctx, db, v); err != nil {
+			return err
+		}
+	}
 
-	return nil
+	return runAfterMigrationHook(ctx, info)
 }
 
 func execQuery(ctx context.Context, fn func(context.Context, string, ...interface{}) (sql.Result, error), query string, args ...interface{}) error {
@@ -0,0 +1,131 @@
+package goose
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+)
+
+// StatementProgressStore records, for a no-transaction migration, how far
+// execution got before a failure so that a retry can skip statements that
+// already succeeded instead of re-running a partially-applied
+// CREATE INDEX CONCURRENTLY or similar non-transactional DDL from scratch.
+type StatementProgressStore interface {
+	// Get returns the index of the last successfully executed statement
+	// for version and the checksum it was recorded against. ok is false if
+	// no progress has been recorded.
+	Get(ctx context.Context, db *sql.DB, version int64) (lastStmtIdx int, checksum string, ok bool, err error)
+	// Save records that the statement at idx completed successfully.
+	Save(ctx context.Context, db *sql.DB, version int64, idx int, checksum string) error
+	// Clear removes any recorded progress for version, called once the
+	// migration completes in full.
+	Clear(ctx context.Context, db *sql.DB, version int64) error
+}
+
+// statementsChecksum hashes the normalized (comment- and blank-line
+// stripped) statements of a migration so that progress recorded against one
+// version of a file is never applied to a changed one.
+func statementsChecksum(statements []string) string {
+	h := sha256.New()
+	for _, s := range statements {
+		h.Write([]byte(clearStatement(s)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// dialectProgressStore is the default StatementProgressStore, backed by a
+// goose_statement_progress table managed alongside the dialect's version
+// table.
+type dialectProgressStore struct{}
+
+const createProgressTableSQL = `CREATE TABLE IF NOT EXISTS goose_statement_progress (
+	version_id BIGINT NOT NULL PRIMARY KEY,
+	last_stmt_idx INTEGER NOT NULL,
+	checksum TEXT NOT NULL
+)`
+
+func (dialectProgressStore) ensureTable(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, createProgressTableSQL); err != nil {
+		return fmt.Errorf("failed to create goose_statement_progress table: %w", err)
+	}
+	return nil
+}
+
+func (s dialectProgressStore) Get(ctx context.Context, db *sql.DB, version int64) (int, string, bool, error) {
+	if err := s.ensureTable(ctx, db); err != nil {
+		return 0, "", false, err
+	}
+	row := db.QueryRowContext(ctx,
+		`SELECT last_stmt_idx, checksum FROM goose_statement_progress WHERE version_id = ?`, version)
+	var idx int
+	var checksum string
+	switch err := row.Scan(&idx, &checksum); err {
+	case nil:
+		return idx, checksum, true, nil
+	case sql.ErrNoRows:
+		return 0, "", false, nil
+	default:
+		return 0, "", false, fmt.Errorf("failed to read statement progress for version %d: %w", version, err)
+	}
+}
+
+func (s dialectProgressStore) Save(ctx context.Context, db *sql.DB, version int64, idx int, checksum string) error {
+	if err := s.ensureTable(ctx, db); err != nil {
+		return err
+	}
+	_, _, ok, err := s.Get(ctx, db, version)
+	if err != nil {
+		return err
+	}
+	if ok {
+		_, err = db.ExecContext(ctx,
+			`UPDATE goose_statement_progress SET last_stmt_idx = ?, checksum = ? WHERE version_id = ?`,
+			idx, checksum, version)
+	} else {
+		_, err = db.ExecContext(ctx,
+			`INSERT INTO goose_statement_progress (version_id, last_stmt_idx, checksum) VALUES (?, ?, ?)`,
+			version, idx, checksum)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save statement progress for version %d: %w", version, err)
+	}
+	return nil
+}
+
+func (s dialectProgressStore) Clear(ctx context.Context, db *sql.DB, version int64) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM goose_statement_progress WHERE version_id = ?`, version); err != nil {
+		return fmt.Errorf("failed to clear statement progress for version %d: %w", version, err)
+	}
+	return nil
+}
+
+// statementProgressStore is the StatementProgressStore consulted by the
+// no-transaction branch of runSQLMigration. It defaults to nil, which
+// preserves the existing behavior of always re-running every statement on
+// retry.
+var statementProgressStore StatementProgressStore
+
+// SetStatementProgressStore enables statement-level checkpointing for
+// no-transaction migrations, using store to persist and look up progress.
+// Passing nil disables checkpointing.
+func SetStatementProgressStore(store StatementProgressStore) {
+	statementProgressStore = store
+}
+
+// ErrChecksumMismatch is returned by the no-transaction resume path when a
+// migration file has changed since progress against it was last recorded.
+var ErrChecksumMismatch = fmt.Errorf("goose: migration file changed since last attempt, refusing to resume")
+
+// ResumeUp runs pending migrations in dir against db, resuming any
+// no-transaction migration that previously failed partway through from its
+// last successfully completed statement, provided the migration file is
+// unchanged. It is equivalent to UpSourceContext with a StatementProgressStore
+// installed via SetStatementProgressStore.
+func ResumeUp(ctx context.Context, db *sql.DB, dir string) error {
+	if statementProgressStore == nil {
+		SetStatementProgressStore(dialectProgressStore{})
+	}
+	return UpSourceContext(ctx, db, dir)
+}
@@ -0,0 +1,96 @@
+package goose
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+// fakeProgressStore is an in-memory StatementProgressStore. The fake driver
+// returns zero rows for every query, so dialectProgressStore backed by it
+// would never find recorded progress.
+type fakeProgressStore struct {
+	saved map[int64]fakeProgress
+}
+
+type fakeProgress struct {
+	idx      int
+	checksum string
+}
+
+func (s *fakeProgressStore) Get(ctx context.Context, db *sql.DB, version int64) (int, string, bool, error) {
+	p, ok := s.saved[version]
+	return p.idx, p.checksum, ok, nil
+}
+
+func (s *fakeProgressStore) Save(ctx context.Context, db *sql.DB, version int64, idx int, checksum string) error {
+	if s.saved == nil {
+		s.saved = make(map[int64]fakeProgress)
+	}
+	s.saved[version] = fakeProgress{idx: idx, checksum: checksum}
+	return nil
+}
+
+func (s *fakeProgressStore) Clear(ctx context.Context, db *sql.DB, version int64) error {
+	delete(s.saved, version)
+	return nil
+}
+
+func TestResumeSkipsCompletedStatementsAfterChecksumMatch(t *testing.T) {
+	db, conn := openFakeDB(t)
+	defer db.Close()
+
+	store := &fakeProgressStore{}
+	SetStatementProgressStore(store)
+	defer SetStatementProgressStore(nil)
+
+	ctx := context.Background()
+	statements := []string{"CREATE INDEX CONCURRENTLY a", "CREATE INDEX CONCURRENTLY b"}
+	checksum := statementsChecksum(statements)
+
+	// Simulate a prior attempt that completed only the first statement.
+	if err := store.Save(ctx, db, 1, 0, checksum); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	if err := runSQLMigration(ctx, db, statements, false, 1, true, true, ""); err != nil {
+		t.Fatalf("runSQLMigration: %v", err)
+	}
+
+	for _, query := range conn.execed {
+		if query == statements[0] {
+			t.Fatalf("expected statement 0 to be skipped on resume, but it ran: %v", conn.execed)
+		}
+	}
+	if len(conn.execed) != 1 || conn.execed[0] != statements[1] {
+		t.Fatalf("expected only statement 1 to run, got %v", conn.execed)
+	}
+
+	if _, _, ok, err := store.Get(ctx, db, 1); err != nil {
+		t.Fatalf("Get: %v", err)
+	} else if ok {
+		t.Fatalf("expected progress to be cleared after migration completed")
+	}
+}
+
+func TestResumeRefusesWhenMigrationFileChanged(t *testing.T) {
+	db, _ := openFakeDB(t)
+	defer db.Close()
+
+	store := &fakeProgressStore{}
+	SetStatementProgressStore(store)
+	defer SetStatementProgressStore(nil)
+
+	ctx := context.Background()
+	original := []string{"CREATE INDEX CONCURRENTLY a", "CREATE INDEX CONCURRENTLY b"}
+	if err := store.Save(ctx, db, 1, 0, statementsChecksum(original)); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	changed := []string{"CREATE INDEX CONCURRENTLY a_renamed", "CREATE INDEX CONCURRENTLY b"}
+	err := runSQLMigration(ctx, db, changed, false, 1, true, true, "")
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package goose
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// UpSourceContext discovers pending SQL migrations and applies them against db, in
+// version order. Discovery reads from the MigrationSource configured via
+// SetSource, falling back to a plain os.DirFS(dir) lookup when none has
+// been set, so SetSource actually changes where migrations are read from
+// instead of being inert configuration.
+//
+// Named UpSourceContext rather than UpContext so it can't be mistaken for,
+// or collide with, the package's existing UpContext entry point (the one
+// driven by AddMigration/Run): that entry point already owns running
+// registered Go migrations and isn't touched by this function.
+//
+// Scope note: this discovers and runs .sql migrations found through a
+// MigrationSource only. Go-based migrations registered via AddMigration
+// are compiled directly into the binary rather than discovered from a
+// source, so they are out of scope for MigrationSource (whose purpose is
+// locating files, not funcs already linked in) and are unaffected by
+// SetSource/UpSourceContext.
+func UpSourceContext(ctx context.Context, db *sql.DB, dir string) error {
+	return runSourceMigrations(ctx, db, dir, source)
+}
+
+// UpContextWithSource is equivalent to UpSourceContext but reads migrations from
+// src instead of the package-level source configured via SetSource. It's
+// the entry point for callers who want to embed migrations (e.g. via
+// embed.FS) without affecting any other goose call in the process.
+func UpContextWithSource(ctx context.Context, db *sql.DB, dir string, src MigrationSource) error {
+	return runSourceMigrations(ctx, db, dir, src)
+}
+
+func runSourceMigrations(ctx context.Context, db *sql.DB, dir string, src MigrationSource) error {
+	if src == nil {
+		src = NewFSSource(os.DirFS(dir))
+	}
+
+	unlock, err := acquireLock(ctx, db)
+	if err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer unlock()
+
+	if strictChecksums && checksumStore != nil {
+		drift, err := Validate(ctx, db, src)
+		if err != nil {
+			return fmt.Errorf("failed to validate migration checksums: %w", err)
+		}
+		if len(drift) > 0 {
+			return fmt.Errorf("goose: refusing to run Up, %d migration(s) changed since they were applied: %v", len(drift), drift)
+		}
+	}
+
+	refs, err := src.List(ctx)
+	if err != nil {
+		return err
+	}
+	refs = append([]MigrationRef(nil), refs...)
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Version < refs[j].Version })
+
+	current, err := versionStore.Current(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	for _, ref := range refs {
+		if ref.Version <= current {
+			continue
+		}
+
+		rc, err := src.Open(ctx, ref)
+		if err != nil {
+			return err
+		}
+		content, err := readAllAndClose(rc)
+		if err != nil {
+			return fmt.Errorf("failed to read migration %q: %w", ref.Name, err)
+		}
+
+		statements, useTx, err := parseSourceMigration(content, true)
+		if err != nil {
+			return fmt.Errorf("failed to parse migration %q: %w", ref.Name, err)
+		}
+
+		if err := runSQLMigration(ctx, db, statements, useTx, ref.Version, true, false, contentChecksum(content)); err != nil {
+			return fmt.Errorf("failed to run migration %q: %w", ref.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func readAllAndClose(rc interface {
+	Read(p []byte) (int, error)
+	Close() error
+}) ([]byte, error) {
+	defer rc.Close()
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(rc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseSourceMigration splits a migration file's content into the statements
+// for the requested direction (up when directionUp is true, down
+// otherwise). See runSQLMigration's doc comment for the section-marker
+// convention this follows; "-- +goose NO TRANSACTION" additionally
+// disables wrapping the selected section's statements in a transaction.
+func parseSourceMigration(content []byte, directionUp bool) (statements []string, useTx bool, err error) {
+	const (
+		sectionNone = iota
+		sectionUp
+		sectionDown
+	)
+
+	useTx = true
+	section := sectionNone
+	var current strings.Builder
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	flush := func() {
+		stmt := strings.TrimSpace(current.String())
+		if stmt != "" && ((section == sectionUp && directionUp) || (section == sectionDown && !directionUp)) {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "-- +goose Up"):
+			flush()
+			section = sectionUp
+			continue
+		case strings.HasPrefix(trimmed, "-- +goose Down"):
+			flush()
+			section = sectionDown
+			continue
+		case strings.HasPrefix(trimmed, "-- +goose NO TRANSACTION"):
+			if (section == sectionUp && directionUp) || (section == sectionDown && !directionUp) {
+				useTx = false
+			}
+			continue
+		}
+
+		if (section == sectionUp && directionUp) || (section == sectionDown && !directionUp) {
+			current.WriteString(line)
+			current.WriteString("\n")
+			if strings.HasSuffix(trimmed, ";") {
+				flush()
+			}
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, false, err
+	}
+	return statements, useTx, nil
+}
@@ -0,0 +1,93 @@
+package goose
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+// literalSource is a MigrationSource over an in-memory set of refs/content,
+// used so tests can exercise UpContextWithSource without touching a real
+// filesystem or the numericComponent-based NewFSSource discovery path.
+type literalSource struct {
+	refs    []MigrationRef
+	content map[int64][]byte
+}
+
+func (s literalSource) List(ctx context.Context) ([]MigrationRef, error) {
+	return s.refs, nil
+}
+
+func (s literalSource) Open(ctx context.Context, ref MigrationRef) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.content[ref.Version])), nil
+}
+
+func TestUpContextWithSourceAppliesPendingMigrations(t *testing.T) {
+	db, conn := openFakeDB(t)
+	defer db.Close()
+
+	store := &fakeVersionStore{}
+	SetVersionStore(store)
+	defer SetVersionStore(nil)
+
+	src := literalSource{
+		refs: []MigrationRef{
+			{Version: 1, Name: "00001_init.sql"},
+			{Version: 2, Name: "00002_add_col.sql"},
+		},
+		content: map[int64][]byte{
+			1: []byte("-- +goose Up\nCREATE TABLE t (id int);\n"),
+			2: []byte("-- +goose Up\nALTER TABLE t ADD COLUMN name text;\n"),
+		},
+	}
+
+	if err := UpContextWithSource(context.Background(), db, "unused", src); err != nil {
+		t.Fatalf("UpContextWithSource: %v", err)
+	}
+
+	if len(store.inserted) != 2 || store.inserted[0] != 1 || store.inserted[1] != 2 {
+		t.Fatalf("inserted versions = %v, want [1 2]", store.inserted)
+	}
+
+	want := []string{"CREATE TABLE t (id int);", "ALTER TABLE t ADD COLUMN name text;"}
+	if len(conn.execed) != len(want) {
+		t.Fatalf("executed = %v, want %v", conn.execed, want)
+	}
+	for i, stmt := range want {
+		if conn.execed[i] != stmt {
+			t.Fatalf("executed[%d] = %q, want %q", i, conn.execed[i], stmt)
+		}
+	}
+}
+
+func TestUpContextWithSourceSkipsAlreadyAppliedVersions(t *testing.T) {
+	db, conn := openFakeDB(t)
+	defer db.Close()
+
+	store := &fakeVersionStore{current: 1}
+	SetVersionStore(store)
+	defer SetVersionStore(nil)
+
+	src := literalSource{
+		refs: []MigrationRef{
+			{Version: 1, Name: "00001_init.sql"},
+			{Version: 2, Name: "00002_add_col.sql"},
+		},
+		content: map[int64][]byte{
+			1: []byte("-- +goose Up\nCREATE TABLE t (id int);\n"),
+			2: []byte("-- +goose Up\nALTER TABLE t ADD COLUMN name text;\n"),
+		},
+	}
+
+	if err := UpContextWithSource(context.Background(), db, "unused", src); err != nil {
+		t.Fatalf("UpContextWithSource: %v", err)
+	}
+
+	if len(store.inserted) != 1 || store.inserted[0] != 2 {
+		t.Fatalf("inserted versions = %v, want [2]", store.inserted)
+	}
+	if len(conn.execed) != 1 || conn.execed[0] != "ALTER TABLE t ADD COLUMN name text;" {
+		t.Fatalf("executed = %v, want only migration 2's statement", conn.execed)
+	}
+}
@@ -0,0 +1,118 @@
+package goose
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSSourceOpenReadsFileContent(t *testing.T) {
+	fsys := fstest.MapFS{
+		"00001_init.sql": {Data: []byte("-- +goose Up\nCREATE TABLE t (id int);\n")},
+	}
+	src := NewFSSource(fsys)
+
+	rc, err := src.Open(context.Background(), MigrationRef{Version: 1, Name: "00001_init.sql"})
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if got, want := string(content), "-- +goose Up\nCREATE TABLE t (id int);\n"; got != want {
+		t.Fatalf("content = %q, want %q", got, want)
+	}
+}
+
+func TestFSSourceOpenMissingFile(t *testing.T) {
+	src := NewFSSource(fstest.MapFS{})
+	if _, err := src.Open(context.Background(), MigrationRef{Version: 1, Name: "missing.sql"}); err == nil {
+		t.Fatal("expected an error opening a missing migration, got nil")
+	}
+}
+
+func TestHTTPSourceListAndOpen(t *testing.T) {
+	const body = "-- +goose Up\nCREATE TABLE t (id int);\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/00001_init.sql" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	refs := []MigrationRef{{Version: 1, Name: "00001_init.sql"}}
+	src := NewHTTPSource(nil, server.URL, refs)
+
+	got, err := src.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(got) != 1 || got[0].Version != 1 {
+		t.Fatalf("List = %v, want %v", got, refs)
+	}
+
+	rc, err := src.Open(context.Background(), refs[0])
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(content) != body {
+		t.Fatalf("content = %q, want %q", content, body)
+	}
+}
+
+type fakeObjectGetter struct {
+	objects map[string][]byte
+}
+
+func (g fakeObjectGetter) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	data, ok := g.objects[bucket+"/"+key]
+	if !ok {
+		return nil, fmt.Errorf("no such object %s/%s", bucket, key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestS3SourceListAndOpen(t *testing.T) {
+	getter := fakeObjectGetter{objects: map[string][]byte{
+		"migrations/00001_init.sql": []byte("-- +goose Up\nCREATE TABLE t (id int);\n"),
+	}}
+	refs := []MigrationRef{{Version: 1, Name: "00001_init.sql"}}
+	src := NewS3Source(getter, "migrations", refs)
+
+	got, err := src.List(context.Background())
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(got) != 1 || got[0].Version != 1 {
+		t.Fatalf("List = %v, want %v", got, refs)
+	}
+
+	rc, err := src.Open(context.Background(), refs[0])
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(content) != "-- +goose Up\nCREATE TABLE t (id int);\n" {
+		t.Fatalf("content = %q", content)
+	}
+}
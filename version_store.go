@@ -0,0 +1,139 @@
+package goose
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// versionStoreExecer is the subset of *sql.Tx and *sql.DB that a
+// VersionStore needs in order to record a version change. Both types
+// satisfy it already, so implementations can be handed either one
+// depending on whether the calling migration runs inside a transaction.
+type versionStoreExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// VersionStore tracks which migrations have been applied. The default
+// implementation records versions in the dialect's goose version table,
+// but callers can register an alternative (e.g. a store backed by a
+// separate metadata database) via SetVersionStore.
+type VersionStore interface {
+	// Insert records that version has been applied.
+	Insert(ctx context.Context, db versionStoreExecer, version int64) error
+	// Delete removes the record that version has been applied.
+	Delete(ctx context.Context, db versionStoreExecer, version int64) error
+	// Current returns the most recently applied version.
+	Current(ctx context.Context, db *sql.DB) (int64, error)
+	// List returns every applied version, most recent first.
+	List(ctx context.Context, db *sql.DB) ([]int64, error)
+}
+
+// dialectVersionStore is the default VersionStore, backed by the version
+// table managed by the configured Dialect.
+type dialectVersionStore struct{}
+
+func (dialectVersionStore) Insert(ctx context.Context, db versionStoreExecer, version int64) error {
+	if err := execQuery(ctx, wrapExecer(db), GetDialect().insertVersionSQL(), version, true); err != nil {
+		return fmt.Errorf("failed to insert new goose version: %w", err)
+	}
+	return nil
+}
+
+func (dialectVersionStore) Delete(ctx context.Context, db versionStoreExecer, version int64) error {
+	if err := execQuery(ctx, wrapExecer(db), GetDialect().deleteVersionSQL(), version); err != nil {
+		return fmt.Errorf("failed to delete goose version: %w", err)
+	}
+	return nil
+}
+
+func (dialectVersionStore) Current(ctx context.Context, db *sql.DB) (int64, error) {
+	row := db.QueryRowContext(ctx, GetDialect().migrationSQL())
+	var version int64
+	switch err := row.Scan(&version); err {
+	case nil:
+		return version, nil
+	case sql.ErrNoRows:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("failed to fetch current goose version: %w", err)
+	}
+}
+
+func (dialectVersionStore) List(ctx context.Context, db *sql.DB) ([]int64, error) {
+	rows, err := db.QueryContext(ctx, GetDialect().migrationSQL())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goose versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []int64
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan goose version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// versionStore is the VersionStore consulted by runSQLMigration.
+var versionStore VersionStore = dialectVersionStore{}
+
+// SetVersionStore overrides the VersionStore used to record applied
+// migrations. Passing nil restores the default dialect-backed store.
+func SetVersionStore(vs VersionStore) {
+	if vs == nil {
+		vs = dialectVersionStore{}
+	}
+	versionStore = vs
+}
+
+func wrapExecer(db versionStoreExecer) func(context.Context, string, ...interface{}) (sql.Result, error) {
+	return db.ExecContext
+}
+
+// MigrationInfo describes the migration a Before/AfterMigration hook is
+// being invoked for.
+type MigrationInfo struct {
+	Version   int64
+	Direction bool // true for Up, false for Down
+}
+
+// MigrationHook is invoked immediately before or after a migration runs,
+// letting callers emit metrics, send notifications, or take backups
+// around the migration boundary. A non-nil error from a before-hook aborts
+// the migration before any statements are executed.
+type MigrationHook func(ctx context.Context, info MigrationInfo) error
+
+var (
+	beforeMigrationHook MigrationHook
+	afterMigrationHook  MigrationHook
+)
+
+// SetBeforeMigrationHook registers a hook invoked immediately before each
+// migration runs. Passing nil disables the hook.
+func SetBeforeMigrationHook(hook MigrationHook) {
+	beforeMigrationHook = hook
+}
+
+// SetAfterMigrationHook registers a hook invoked immediately after each
+// migration completes successfully. Passing nil disables the hook.
+func SetAfterMigrationHook(hook MigrationHook) {
+	afterMigrationHook = hook
+}
+
+func runBeforeMigrationHook(ctx context.Context, info MigrationInfo) error {
+	if beforeMigrationHook == nil {
+		return nil
+	}
+	return beforeMigrationHook(ctx, info)
+}
+
+func runAfterMigrationHook(ctx context.Context, info MigrationInfo) error {
+	if afterMigrationHook == nil {
+		return nil
+	}
+	return afterMigrationHook(ctx, info)
+}
@@ -0,0 +1,128 @@
+package goose
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+var errHookRefused = errors.New("hook refused")
+
+type fakeVersionStore struct {
+	inserted []int64
+	deleted  []int64
+	current  int64
+}
+
+func (s *fakeVersionStore) Insert(ctx context.Context, db versionStoreExecer, version int64) error {
+	s.inserted = append(s.inserted, version)
+	s.current = version
+	return nil
+}
+
+func (s *fakeVersionStore) Delete(ctx context.Context, db versionStoreExecer, version int64) error {
+	s.deleted = append(s.deleted, version)
+	return nil
+}
+
+func (s *fakeVersionStore) Current(ctx context.Context, db *sql.DB) (int64, error) {
+	return s.current, nil
+}
+
+func (s *fakeVersionStore) List(ctx context.Context, db *sql.DB) ([]int64, error) {
+	return s.inserted, nil
+}
+
+func TestVersionStoreRecordsInsertOnUp(t *testing.T) {
+	db, _ := openFakeDB(t)
+	defer db.Close()
+
+	store := &fakeVersionStore{}
+	SetVersionStore(store)
+	defer SetVersionStore(nil)
+
+	if err := runSQLMigration(context.Background(), db, []string{"CREATE TABLE t (id int)"}, true, 7, true, false, ""); err != nil {
+		t.Fatalf("runSQLMigration: %v", err)
+	}
+
+	if len(store.inserted) != 1 || store.inserted[0] != 7 {
+		t.Fatalf("inserted = %v, want [7]", store.inserted)
+	}
+}
+
+func TestVersionStoreRecordsDeleteOnDown(t *testing.T) {
+	db, _ := openFakeDB(t)
+	defer db.Close()
+
+	store := &fakeVersionStore{}
+	SetVersionStore(store)
+	defer SetVersionStore(nil)
+
+	if err := runSQLMigration(context.Background(), db, []string{"DROP TABLE t"}, true, 7, false, false, ""); err != nil {
+		t.Fatalf("runSQLMigration: %v", err)
+	}
+
+	if len(store.deleted) != 1 || store.deleted[0] != 7 {
+		t.Fatalf("deleted = %v, want [7]", store.deleted)
+	}
+}
+
+func TestMigrationHooksFireBeforeAndAfterInsert(t *testing.T) {
+	db, _ := openFakeDB(t)
+	defer db.Close()
+
+	store := &fakeVersionStore{}
+	SetVersionStore(store)
+	defer SetVersionStore(nil)
+
+	var order []string
+	SetBeforeMigrationHook(func(ctx context.Context, info MigrationInfo) error {
+		order = append(order, "before")
+		if len(store.inserted) != 0 {
+			t.Fatalf("before-hook fired after the version was recorded")
+		}
+		return nil
+	})
+	SetAfterMigrationHook(func(ctx context.Context, info MigrationInfo) error {
+		order = append(order, "after")
+		if len(store.inserted) != 1 {
+			t.Fatalf("after-hook fired before the version was recorded")
+		}
+		return nil
+	})
+	defer func() {
+		SetBeforeMigrationHook(nil)
+		SetAfterMigrationHook(nil)
+	}()
+
+	if err := runSQLMigration(context.Background(), db, []string{"CREATE TABLE t (id int)"}, true, 1, true, false, ""); err != nil {
+		t.Fatalf("runSQLMigration: %v", err)
+	}
+
+	if len(order) != 2 || order[0] != "before" || order[1] != "after" {
+		t.Fatalf("hook order = %v, want [before after]", order)
+	}
+}
+
+func TestBeforeMigrationHookErrorAbortsMigration(t *testing.T) {
+	db, conn := openFakeDB(t)
+	defer db.Close()
+
+	store := &fakeVersionStore{}
+	SetVersionStore(store)
+	defer SetVersionStore(nil)
+
+	SetBeforeMigrationHook(func(ctx context.Context, info MigrationInfo) error {
+		return errHookRefused
+	})
+	defer SetBeforeMigrationHook(nil)
+
+	err := runSQLMigration(context.Background(), db, []string{"CREATE TABLE t (id int)"}, true, 1, true, false, "")
+	if err == nil {
+		t.Fatal("expected an error when the before-hook refuses")
+	}
+	if len(conn.execed) != 0 {
+		t.Fatalf("expected no statements to run when the before-hook refuses, got %v", conn.execed)
+	}
+}